@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// tokenRefreshSkew is how far ahead of a token's reported expiry
+// CachingTokenSource fetches a replacement.
+const tokenRefreshSkew = 30 * time.Second
+
+// BasicAuthMiddleware injects HTTP Basic authentication credentials into
+// every outgoing request.
+func BasicAuthMiddleware(username, password string) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			r.SetBasicAuth(username, password)
+			return next.RoundTrip(r)
+		})
+	}
+}
+
+// TokenSource supplies a bearer token for each request. Implementations that
+// wrap a refreshable credential (e.g. OAuth2) should cache the token until it
+// is close to expiry; see CachingTokenSource.
+type TokenSource interface {
+	Token(ctx context.Context) (string, error)
+}
+
+// StaticToken is a TokenSource that always returns the same token.
+type StaticToken string
+
+func (t StaticToken) Token(context.Context) (string, error) {
+	return string(t), nil
+}
+
+// CachingTokenSource wraps a token-fetching function that also reports an
+// expiry, refreshing the token only once it is within tokenRefreshSkew of
+// that expiry. This is the shape an OAuth2 client-credentials or refresh-token
+// exchange naturally takes.
+type CachingTokenSource struct {
+	New func(ctx context.Context) (token string, expiry time.Time, err error)
+
+	mu     sync.Mutex
+	token  string
+	expiry time.Time
+}
+
+func (c *CachingTokenSource) Token(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.token != "" && time.Until(c.expiry) > tokenRefreshSkew {
+		return c.token, nil
+	}
+
+	token, expiry, err := c.New(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	c.token, c.expiry = token, expiry
+	return c.token, nil
+}
+
+// BearerAuthMiddleware injects an "Authorization: Bearer <token>" header
+// using the token returned by src, re-fetching it on every request so
+// TokenSource implementations can transparently refresh expired tokens.
+func BearerAuthMiddleware(src TokenSource) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			token, err := src.Token(r.Context())
+			if err != nil {
+				return nil, fmt.Errorf("failed to obtain bearer token: %w", err)
+			}
+
+			r.Header.Set("Authorization", "Bearer "+token)
+			return next.RoundTrip(r)
+		})
+	}
+}
@@ -0,0 +1,299 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CacheEntry is a stored response plus what's needed to judge its freshness
+// and, once stale, revalidate it.
+type CacheEntry struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+
+	StoredAt       time.Time
+	MaxAge         time.Duration
+	MustRevalidate bool // Cache-Control: no-cache was present
+
+	ETag         string
+	LastModified string
+
+	// Vary lists the request header names this entry varies on, and
+	// VaryValues captures their values from the request that produced it.
+	Vary       []string
+	VaryValues map[string]string
+}
+
+func (e *CacheEntry) fresh() bool {
+	return !e.MustRevalidate && time.Since(e.StoredAt) < e.MaxAge
+}
+
+func (e *CacheEntry) matchesVary(r *http.Request) bool {
+	for _, h := range e.Vary {
+		if e.VaryValues[h] != r.Header.Get(h) {
+			return false
+		}
+	}
+	return true
+}
+
+// Cache stores cached responses keyed by method+URL. Implementations must be
+// safe for concurrent use. InMemoryCache is the built-in implementation;
+// implement this interface to plug in Redis, disk, or another backend.
+type Cache interface {
+	Get(key string) (*CacheEntry, bool)
+	Set(key string, entry *CacheEntry)
+	Delete(key string)
+}
+
+// InMemoryCache is a Cache backed by an in-process map. It never evicts
+// entries, so callers needing bounded memory should wrap it or provide their
+// own Cache implementation.
+type InMemoryCache struct {
+	mu      sync.RWMutex
+	entries map[string]*CacheEntry
+}
+
+func NewInMemoryCache() *InMemoryCache {
+	return &InMemoryCache{entries: make(map[string]*CacheEntry)}
+}
+
+func (c *InMemoryCache) Get(key string) (*CacheEntry, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	e, ok := c.entries[key]
+	return e, ok
+}
+
+func (c *InMemoryCache) Set(key string, entry *CacheEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key] = entry
+}
+
+func (c *InMemoryCache) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, key)
+}
+
+// cachedDo serves r from c.options.Cache when possible, revalidating stale
+// entries with If-None-Match/If-Modified-Since, and otherwise dispatches
+// normally and stores the result if it's cacheable. Only GET and HEAD are
+// cached.
+func (c *Client) cachedDo(r *http.Request) (*http.Response, error) {
+	cache := c.options.Cache
+	if cache == nil || !cacheableRequestMethod(r.Method) {
+		return c.doWithRetry(r)
+	}
+
+	key := cacheKey(r)
+
+	entry, hit := cache.Get(key)
+	if hit && !entry.matchesVary(r) {
+		hit = false
+	}
+
+	if hit && entry.fresh() {
+		return entryToResponse(entry, r), nil
+	}
+
+	if hit {
+		addValidators(r, entry)
+	}
+
+	resp, err := c.doWithRetry(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if hit && resp.StatusCode == http.StatusNotModified {
+		_ = resp.Body.Close()
+		refreshed := refreshEntry(entry, resp.Header)
+		cache.Set(key, refreshed)
+		return entryToResponse(refreshed, r), nil
+	}
+
+	return c.maybeStoreResponse(key, r, resp)
+}
+
+func cacheableRequestMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead
+}
+
+func cacheKey(r *http.Request) string {
+	return r.Method + " " + r.URL.String()
+}
+
+func addValidators(r *http.Request, e *CacheEntry) {
+	if e.ETag != "" {
+		r.Header.Set("If-None-Match", e.ETag)
+	}
+	if e.LastModified != "" {
+		r.Header.Set("If-Modified-Since", e.LastModified)
+	}
+}
+
+func entryToResponse(e *CacheEntry, r *http.Request) *http.Response {
+	return &http.Response{
+		StatusCode: e.StatusCode,
+		Status:     http.StatusText(e.StatusCode),
+		Proto:      r.Proto,
+		Header:     e.Header.Clone(),
+		Body:       io.NopCloser(bytes.NewReader(e.Body)),
+		Request:    r,
+	}
+}
+
+// maybeStoreResponse buffers and stores resp under key if its Cache-Control
+// headers make it cacheable, returning a response whose body is safe to read
+// again (the original body is consumed in the process).
+func (c *Client) maybeStoreResponse(key string, r *http.Request, resp *http.Response) (*http.Response, error) {
+	if resp.StatusCode != http.StatusOK {
+		return resp, nil
+	}
+
+	cc := parseCacheControl(resp.Header)
+	if cc.noStore || !cc.hasMaxAge {
+		return resp, nil
+	}
+
+	vary, cacheable := varyHeaderNames(resp.Header)
+	if !cacheable {
+		return resp, nil
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to buffer response body for caching: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	c.options.Cache.Set(key, &CacheEntry{
+		StatusCode:     resp.StatusCode,
+		Header:         resp.Header.Clone(),
+		Body:           body,
+		StoredAt:       time.Now(),
+		MaxAge:         cc.maxAge,
+		MustRevalidate: cc.noCache,
+		ETag:           resp.Header.Get("ETag"),
+		LastModified:   resp.Header.Get("Last-Modified"),
+		Vary:           vary,
+		VaryValues:     captureVaryValues(vary, r.Header),
+	})
+
+	return resp, nil
+}
+
+func refreshEntry(e *CacheEntry, h http.Header) *CacheEntry {
+	merged := e.Header.Clone()
+	for k, vs := range h {
+		merged[k] = vs
+	}
+
+	cc := parseCacheControl(merged)
+	maxAge := e.MaxAge
+	if cc.hasMaxAge {
+		maxAge = cc.maxAge
+	}
+
+	etag := e.ETag
+	if v := h.Get("ETag"); v != "" {
+		etag = v
+	}
+
+	lastModified := e.LastModified
+	if v := h.Get("Last-Modified"); v != "" {
+		lastModified = v
+	}
+
+	return &CacheEntry{
+		StatusCode:     e.StatusCode,
+		Header:         merged,
+		Body:           e.Body,
+		StoredAt:       time.Now(),
+		MaxAge:         maxAge,
+		MustRevalidate: cc.noCache,
+		ETag:           etag,
+		LastModified:   lastModified,
+		Vary:           e.Vary,
+		VaryValues:     e.VaryValues,
+	}
+}
+
+type cacheControl struct {
+	noStore   bool
+	noCache   bool
+	private   bool
+	maxAge    time.Duration
+	hasMaxAge bool
+}
+
+// parseCacheControl honors the max-age, no-store, no-cache, and private
+// directives; private is tracked but doesn't block storage here, since this
+// is a private (single-user) cache, not a shared one.
+func parseCacheControl(h http.Header) cacheControl {
+	var cc cacheControl
+
+	for _, part := range strings.Split(h.Get("Cache-Control"), ",") {
+		k, v, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch strings.ToLower(strings.TrimSpace(k)) {
+		case "no-store":
+			cc.noStore = true
+		case "no-cache":
+			cc.noCache = true
+		case "private":
+			cc.private = true
+		case "max-age":
+			if secs, err := strconv.Atoi(strings.TrimSpace(v)); err == nil && secs >= 0 {
+				cc.maxAge = time.Duration(secs) * time.Second
+				cc.hasMaxAge = true
+			}
+		}
+	}
+
+	return cc
+}
+
+// varyHeaderNames parses the Vary header into the request header names the
+// response varies on. It reports cacheable=false for "Vary: *", which by
+// definition can never be safely reused from cache.
+func varyHeaderNames(h http.Header) (names []string, cacheable bool) {
+	v := h.Get("Vary")
+	if v == "" {
+		return nil, true
+	}
+
+	for _, n := range strings.Split(v, ",") {
+		n = strings.TrimSpace(n)
+		if n == "*" {
+			return nil, false
+		}
+		if n != "" {
+			names = append(names, n)
+		}
+	}
+
+	return names, true
+}
+
+func captureVaryValues(names []string, h http.Header) map[string]string {
+	if len(names) == 0 {
+		return nil
+	}
+
+	values := make(map[string]string, len(names))
+	for _, n := range names {
+		values[n] = h.Get(n)
+	}
+
+	return values
+}
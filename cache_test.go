@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCacheFreshHit(t *testing.T) {
+	ctx := context.Background()
+
+	var hits int
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.Header().Set("Cache-Control", "max-age=60")
+		w.Write([]byte(`{"status":"OK"}`)) //nolint
+	}))
+	defer s.Close()
+
+	c := New(nil, &Options{Cache: NewInMemoryCache()})
+
+	var result testPayload
+	require.NoError(t, c.Get(ctx, s.URL+"/cached", &result, nil))
+	require.NoError(t, c.Get(ctx, s.URL+"/cached", &result, nil))
+
+	assert.Equal(t, 1, hits, "second request should be served from cache")
+	assert.Equal(t, "OK", result.Status)
+}
+
+func TestCacheNoStore(t *testing.T) {
+	ctx := context.Background()
+
+	var hits int
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.Header().Set("Cache-Control", "no-store, max-age=60")
+		w.Write([]byte(`{"status":"OK"}`)) //nolint
+	}))
+	defer s.Close()
+
+	c := New(nil, &Options{Cache: NewInMemoryCache()})
+
+	var result testPayload
+	require.NoError(t, c.Get(ctx, s.URL+"/nostore", &result, nil))
+	require.NoError(t, c.Get(ctx, s.URL+"/nostore", &result, nil))
+
+	assert.Equal(t, 2, hits, "no-store responses must never be served from cache")
+}
+
+func TestCacheRevalidation(t *testing.T) {
+	ctx := context.Background()
+
+	var hits int
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("Cache-Control", "no-cache, max-age=60")
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte(`{"status":"OK"}`)) //nolint
+	}))
+	defer s.Close()
+
+	c := New(nil, &Options{Cache: NewInMemoryCache()})
+
+	var result testPayload
+	require.NoError(t, c.Get(ctx, s.URL+"/revalidate", &result, nil))
+	require.NoError(t, c.Get(ctx, s.URL+"/revalidate", &result, nil))
+
+	assert.Equal(t, 2, hits, "no-cache entries must be revalidated on every use")
+	assert.Equal(t, "OK", result.Status)
+}
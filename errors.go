@@ -0,0 +1,57 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// ResponseError is returned for any response whose status is not one the
+// client treats as success (200, 201, 202, 204, or 304). It carries the
+// information mature API clients (go-github, Strava) typically expose so
+// callers can inspect validation messages, rate-limit headers, or request IDs
+// without re-parsing the response themselves.
+//
+// Callers can still match on the existing sentinels (ErrNotFound,
+// ErrBadRequest, etc.) via errors.Is/errors.As, since ResponseError unwraps to
+// the sentinel it was classified under.
+type ResponseError struct {
+	StatusCode int
+	Status     string
+	Method     string
+	URL        string
+	Header     http.Header
+	Body       []byte
+
+	// JSON holds the response body decoded as JSON, if it parsed as such.
+	// Nil otherwise.
+	JSON any
+
+	sentinel error
+}
+
+func newResponseError(r *http.Request, resp *http.Response, body []byte) *ResponseError {
+	e := &ResponseError{
+		StatusCode: resp.StatusCode,
+		Status:     resp.Status,
+		Method:     r.Method,
+		URL:        r.URL.String(),
+		Header:     resp.Header.Clone(),
+		Body:       body,
+	}
+
+	var payload any
+	if json.Unmarshal(body, &payload) == nil {
+		e.JSON = payload
+	}
+
+	return e
+}
+
+func (e *ResponseError) Error() string {
+	return fmt.Sprintf("%s %s: %s (%d): %s", e.Method, e.URL, e.Status, e.StatusCode, e.Body)
+}
+
+func (e *ResponseError) Unwrap() error {
+	return e.sentinel
+}
@@ -7,9 +7,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"log"
 	"net/http"
-	"net/http/httputil"
 )
 
 const failMsg = "failed to create %s request: %w"
@@ -33,12 +31,46 @@ var (
 )
 
 type Options struct {
+	// Debug logs each request and response via LoggingMiddleware(nil), which
+	// writes to slog.Default(): failed round trips at Error level, completed
+	// ones at Debug level, so the latter appear only if the default logger's
+	// level admits Debug records. For more control (a custom logger, or
+	// combining with other middlewares), add LoggingMiddleware to Middlewares
+	// directly instead.
 	Debug bool
+
+	// Retry configures automatic retries for ErrRetriable and
+	// transport-level errors. Nil disables retries.
+	Retry *RetryPolicy
+
+	// Middlewares wrap the underlying http.RoundTripper, applied in order
+	// (the first middleware is outermost). Use these to add authentication,
+	// logging, metrics, or other cross-cutting behavior. See
+	// BasicAuthMiddleware, BearerAuthMiddleware, LoggingMiddleware,
+	// MetricsMiddleware, and RequestIDMiddleware.
+	Middlewares []Middleware
+
+	// RateLimiter, if set, throttles outgoing requests client-side. Nil
+	// disables rate limiting.
+	RateLimiter *RateLimiterConfig
+
+	// MaxConcurrent bounds the number of in-flight requests. Zero means
+	// unbounded.
+	MaxConcurrent int
+
+	// Cache, if set, enables response caching for GET/HEAD requests per a
+	// subset of RFC 7234 (max-age, no-store, no-cache, private, and
+	// conditional revalidation via ETag/Last-Modified). Nil disables
+	// caching.
+	Cache Cache
 }
 
 type Client struct {
 	httpClient *http.Client
 	options    *Options
+
+	limiter *rateLimiter
+	sem     semaphore
 }
 
 func (c *Client) Head(ctx context.Context, apiURL string, payload []byte, headers *http.Header) error {
@@ -130,11 +162,6 @@ func (c *Client) newRequest(ctx context.Context, method, apiURL string, payload
 		return nil, fmt.Errorf("failed to create HTTP request: %w", err)
 	}
 
-	if c.options.Debug {
-		body, _ := httputil.DumpRequest(req, true)
-		log.Printf("%s", body)
-	}
-
 	req = req.WithContext(ctx)
 	return req, nil
 }
@@ -144,7 +171,7 @@ func (c *Client) doRequest(r *http.Request, v any, headers *http.Header) error {
 		r.Header = *headers
 	}
 
-	resp, err := c.do(r)
+	resp, err := c.cachedDo(r)
 	if err != nil {
 		return err
 	}
@@ -180,21 +207,31 @@ func (c *Client) doRequest(r *http.Request, v any, headers *http.Header) error {
 }
 
 func (c *Client) do(r *http.Request) (*http.Response, error) {
-	resp, err := c.httpClient.Do(r)
-	if err != nil {
-		return nil, fmt.Errorf("failed to make request [%s:%s]: %w", r.Method, r.URL.String(), err)
+	if c.limiter != nil {
+		if err := c.limiter.forHost(r.URL.Host).wait(r.Context()); err != nil {
+			return nil, err
+		}
 	}
 
-	if c.options.Debug {
-		body, _ := httputil.DumpResponse(resp, true)
-		log.Printf("%s", body)
+	if err := c.sem.acquire(r.Context()); err != nil {
+		return nil, err
+	}
+	defer c.sem.release()
+
+	resp, err := c.httpClient.Do(r)
+	if err != nil {
+		return nil, errors.Join(
+			fmt.Errorf("failed to make request [%s:%s]: %w", r.Method, r.URL.String(), err),
+			ErrRetriable,
+		)
 	}
 
 	switch resp.StatusCode {
 	case http.StatusOK,
 		http.StatusCreated,
 		http.StatusAccepted,
-		http.StatusNoContent:
+		http.StatusNoContent,
+		http.StatusNotModified: // a valid outcome of a cache-conditional request
 		return resp, nil
 	}
 
@@ -202,29 +239,35 @@ func (c *Client) do(r *http.Request) (*http.Response, error) {
 
 	b, _ := io.ReadAll(resp.Body)
 
+	respErr := newResponseError(r, resp, b)
+
 	// if we get here there was an error
 	switch resp.StatusCode {
 	case http.StatusNotFound:
-		return nil, ErrNotFound
+		respErr.sentinel = ErrNotFound
 	case http.StatusUnauthorized, http.StatusForbidden:
-		return nil, ErrUserAccessDenied
+		respErr.sentinel = ErrUserAccessDenied
 	case http.StatusTooManyRequests:
-		return nil, errors.Join(ErrTooManyRequests, ErrRetriable)
+		respErr.sentinel = errors.Join(ErrTooManyRequests, ErrRetriable)
+		return nil, c.wrapRetryAfter(r, respErr, resp.Header)
 	case http.StatusUnprocessableEntity:
-		return nil, errors.Join(ErrUnprocessableEntity, ErrRetriable)
+		respErr.sentinel = errors.Join(ErrUnprocessableEntity, ErrRetriable)
 	case http.StatusInternalServerError:
-		return nil, errors.Join(ErrInternalServerError, ErrRetriable)
+		respErr.sentinel = errors.Join(ErrInternalServerError, ErrRetriable)
 	case http.StatusBadGateway:
-		return nil, errors.Join(ErrBadGateway, ErrRetriable)
+		respErr.sentinel = errors.Join(ErrBadGateway, ErrRetriable)
 	case http.StatusServiceUnavailable:
-		return nil, errors.Join(ErrServiceUnavailable, ErrRetriable)
+		respErr.sentinel = errors.Join(ErrServiceUnavailable, ErrRetriable)
+		return nil, c.wrapRetryAfter(r, respErr, resp.Header)
 	case http.StatusGatewayTimeout:
-		return nil, errors.Join(ErrGatewayTimeout, ErrRetriable)
+		respErr.sentinel = errors.Join(ErrGatewayTimeout, ErrRetriable)
 	case http.StatusBadRequest:
-		return nil, ErrBadRequest
+		respErr.sentinel = ErrBadRequest
+	default:
+		respErr.sentinel = ErrUnhandled
 	}
 
-	return nil, errors.Join(fmt.Errorf("request failed, %d status code received: %s", resp.StatusCode, b), ErrUnhandled)
+	return nil, respErr
 }
 
 func New(httpClient *http.Client, options *Options) *Client {
@@ -236,8 +279,26 @@ func New(httpClient *http.Client, options *Options) *Client {
 		options = &Options{}
 	}
 
+	mws := options.Middlewares
+	if options.Debug {
+		mws = append([]Middleware{LoggingMiddleware(nil)}, mws...)
+	}
+
+	if len(mws) > 0 {
+		base := httpClient.Transport
+		if base == nil {
+			base = http.DefaultTransport
+		}
+
+		cloned := *httpClient
+		cloned.Transport = chainMiddleware(base, mws...)
+		httpClient = &cloned
+	}
+
 	return &Client{
 		httpClient: httpClient,
 		options:    options,
+		limiter:    newRateLimiter(options.RateLimiter),
+		sem:        newSemaphore(options.MaxConcurrent),
 	}
 }
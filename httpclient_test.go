@@ -228,3 +228,26 @@ func TestDo(t *testing.T) {
 		require.ErrorIs(t, err, test.e)
 	}
 }
+
+func TestResponseError(t *testing.T) {
+	ctx := context.Background()
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Request-ID", "req-123")
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		_, _ = w.Write([]byte(`{"message":"invalid field"}`))
+	}))
+	defer s.Close()
+
+	c := New(http.DefaultClient, &Options{})
+
+	err := c.Get(ctx, s.URL+"/validate", nil, nil)
+	require.Error(t, err)
+	require.ErrorIs(t, err, ErrUnprocessableEntity)
+
+	var respErr *ResponseError
+	require.ErrorAs(t, err, &respErr)
+	assert.Equal(t, http.StatusUnprocessableEntity, respErr.StatusCode)
+	assert.Equal(t, "req-123", respErr.Header.Get("X-Request-ID"))
+	assert.Equal(t, map[string]any{"message": "invalid field"}, respErr.JSON)
+}
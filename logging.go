@@ -0,0 +1,44 @@
+package main
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// LoggingMiddleware logs each request and response via logger, replacing the
+// old Debug-triggered httputil.DumpRequest/DumpResponse output. A nil logger
+// falls back to slog.Default().
+func LoggingMiddleware(logger *slog.Logger) Middleware {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			start := time.Now()
+
+			resp, err := next.RoundTrip(r)
+			duration := time.Since(start)
+
+			if err != nil {
+				logger.Error("http request failed",
+					"method", r.Method,
+					"url", r.URL.String(),
+					"duration", duration,
+					"error", err,
+				)
+				return resp, err
+			}
+
+			logger.Debug("http request",
+				"method", r.Method,
+				"url", r.URL.String(),
+				"status", resp.StatusCode,
+				"duration", duration,
+			)
+
+			return resp, nil
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"net/http"
+	"time"
+)
+
+// MetricsRecorder receives the outcome of every request so callers can feed
+// it into Prometheus (request count, latency histograms per method/host/
+// status) or any other metrics backend, without this package depending on
+// one directly.
+type MetricsRecorder interface {
+	ObserveRequest(method, host string, statusCode int, duration time.Duration)
+}
+
+// MetricsMiddleware reports every request's method, host, status code, and
+// latency to rec. statusCode is 0 if the round trip failed before a response
+// was received.
+func MetricsMiddleware(rec MetricsRecorder) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			start := time.Now()
+
+			resp, err := next.RoundTrip(r)
+
+			statusCode := 0
+			if resp != nil {
+				statusCode = resp.StatusCode
+			}
+
+			rec.ObserveRequest(r.Method, r.URL.Host, statusCode, time.Since(start))
+
+			return resp, err
+		})
+	}
+}
@@ -0,0 +1,24 @@
+package main
+
+import "net/http"
+
+// RoundTripperFunc adapts a function to the http.RoundTripper interface.
+type RoundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f RoundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+// Middleware wraps an http.RoundTripper to add cross-cutting behavior, such
+// as authentication, logging, or metrics, to every request a Client makes.
+// Middlewares are applied in the order given: the first middleware is
+// outermost and sees the request first and the response last.
+type Middleware func(http.RoundTripper) http.RoundTripper
+
+func chainMiddleware(base http.RoundTripper, mws ...Middleware) http.RoundTripper {
+	rt := base
+	for i := len(mws) - 1; i >= 0; i-- {
+		rt = mws[i](rt)
+	}
+	return rt
+}
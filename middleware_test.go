@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeMetricsRecorder struct {
+	method, host string
+	statusCode   int
+}
+
+func (f *fakeMetricsRecorder) ObserveRequest(method, host string, statusCode int, _ time.Duration) {
+	f.method, f.host, f.statusCode = method, host, statusCode
+}
+
+func TestMiddlewareChain(t *testing.T) {
+	ctx := context.Background()
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "testuser", func() string { u, _, _ := r.BasicAuth(); return u }())
+		assert.NotEmpty(t, r.Header.Get(RequestIDHeader))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer s.Close()
+
+	rec := &fakeMetricsRecorder{}
+
+	c := New(nil, &Options{
+		Middlewares: []Middleware{
+			BasicAuthMiddleware("testuser", "testpass"),
+			RequestIDMiddleware(),
+			MetricsMiddleware(rec),
+		},
+	})
+
+	err := c.Get(ctx, s.URL+"/ping", nil, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.MethodGet, rec.method)
+	assert.Equal(t, http.StatusOK, rec.statusCode)
+}
+
+func TestBearerAuthMiddleware(t *testing.T) {
+	ctx := context.Background()
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer my-token", r.Header.Get("Authorization"))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer s.Close()
+
+	c := New(nil, &Options{
+		Middlewares: []Middleware{BearerAuthMiddleware(StaticToken("my-token"))},
+	})
+
+	err := c.Get(ctx, s.URL+"/ping", nil, nil)
+	require.NoError(t, err)
+}
+
+func TestCachingTokenSourceReusesTokenBeforeSkew(t *testing.T) {
+	ctx := context.Background()
+
+	var calls int32
+	src := &CachingTokenSource{
+		New: func(context.Context) (string, time.Time, error) {
+			n := atomic.AddInt32(&calls, 1)
+			// Expiry is well beyond tokenRefreshSkew, so the cached token
+			// should be reused rather than refetched.
+			return fmt.Sprintf("token-%d", n), time.Now().Add(tokenRefreshSkew + time.Minute), nil
+		},
+	}
+
+	first, err := src.Token(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "token-1", first)
+
+	second, err := src.Token(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "token-1", second)
+	assert.EqualValues(t, 1, atomic.LoadInt32(&calls))
+}
+
+func TestCachingTokenSourceRefreshesNearExpiry(t *testing.T) {
+	ctx := context.Background()
+
+	var calls int32
+	src := &CachingTokenSource{
+		New: func(context.Context) (string, time.Time, error) {
+			n := atomic.AddInt32(&calls, 1)
+			// Expiry is inside tokenRefreshSkew from the moment it's
+			// minted, so every call should refetch.
+			return fmt.Sprintf("token-%d", n), time.Now().Add(time.Millisecond), nil
+		},
+	}
+
+	first, err := src.Token(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "token-1", first)
+
+	second, err := src.Token(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "token-2", second)
+	assert.EqualValues(t, 2, atomic.LoadInt32(&calls))
+}
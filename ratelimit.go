@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// RateLimiterConfig configures client-side request throttling.
+type RateLimiterConfig struct {
+	// RequestsPerSecond is the sustained rate allowed.
+	RequestsPerSecond float64
+
+	// Burst is the maximum burst size.
+	Burst int
+
+	// PerHost, if true, gives each request's URL host its own limiter
+	// instead of sharing one limiter across the whole Client. Use this when
+	// a single Client talks to multiple upstreams that should be throttled
+	// independently.
+	PerHost bool
+}
+
+// hostLimiter paces requests to a single upstream and can be throttled back
+// in response to a Retry-After header.
+type hostLimiter struct {
+	limiter  *rate.Limiter
+	baseRate rate.Limit
+
+	mu           sync.Mutex
+	blockedUntil time.Time
+}
+
+func newHostLimiter(cfg RateLimiterConfig) *hostLimiter {
+	baseRate := rate.Limit(cfg.RequestsPerSecond)
+	return &hostLimiter{limiter: rate.NewLimiter(baseRate, cfg.Burst), baseRate: baseRate}
+}
+
+func (hl *hostLimiter) wait(ctx context.Context) error {
+	hl.mu.Lock()
+	until := hl.blockedUntil
+	hl.mu.Unlock()
+
+	if d := time.Until(until); d > 0 {
+		t := time.NewTimer(d)
+		defer t.Stop()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-t.C:
+		}
+	}
+
+	return hl.limiter.Wait(ctx)
+}
+
+// throttle backs the limiter off in response to a server-provided
+// Retry-After delay: it blocks further admissions until delay elapses and
+// halves the steady-state rate to ease off a struggling upstream. The
+// reduction is temporary — once delay has passed, the rate is restored to
+// its originally configured value, so an occasional 429/503 doesn't
+// permanently ratchet the client down.
+func (hl *hostLimiter) throttle(delay time.Duration) {
+	hl.mu.Lock()
+	hl.blockedUntil = time.Now().Add(delay)
+	hl.mu.Unlock()
+
+	if reduced := hl.limiter.Limit() / 2; reduced > 0 {
+		hl.limiter.SetLimit(reduced)
+	}
+
+	time.AfterFunc(delay, func() {
+		hl.limiter.SetLimit(hl.baseRate)
+	})
+}
+
+// rateLimiter owns either one shared hostLimiter or one per host, depending
+// on RateLimiterConfig.PerHost.
+type rateLimiter struct {
+	cfg RateLimiterConfig
+
+	shared *hostLimiter
+
+	mu      sync.Mutex
+	perHost map[string]*hostLimiter
+}
+
+func newRateLimiter(cfg *RateLimiterConfig) *rateLimiter {
+	if cfg == nil {
+		return nil
+	}
+
+	rl := &rateLimiter{cfg: *cfg}
+	if cfg.PerHost {
+		rl.perHost = make(map[string]*hostLimiter)
+	} else {
+		rl.shared = newHostLimiter(*cfg)
+	}
+
+	return rl
+}
+
+func (rl *rateLimiter) forHost(host string) *hostLimiter {
+	if rl.shared != nil {
+		return rl.shared
+	}
+
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	hl, ok := rl.perHost[host]
+	if !ok {
+		hl = newHostLimiter(rl.cfg)
+		rl.perHost[host] = hl
+	}
+
+	return hl
+}
+
+// semaphore bounds the number of in-flight requests. A nil semaphore imposes
+// no limit.
+type semaphore chan struct{}
+
+func newSemaphore(n int) semaphore {
+	if n <= 0 {
+		return nil
+	}
+	return make(semaphore, n)
+}
+
+func (s semaphore) acquire(ctx context.Context) error {
+	if s == nil {
+		return nil
+	}
+
+	select {
+	case s <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s semaphore) release() {
+	if s == nil {
+		return
+	}
+	<-s
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimiter(t *testing.T) {
+	ctx := context.Background()
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer s.Close()
+
+	c := New(nil, &Options{
+		RateLimiter: &RateLimiterConfig{RequestsPerSecond: 5, Burst: 1},
+	})
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		require.NoError(t, c.Get(ctx, s.URL+"/ping", nil, nil))
+	}
+	elapsed := time.Since(start)
+
+	assert.GreaterOrEqual(t, elapsed, 300*time.Millisecond)
+}
+
+func TestMaxConcurrent(t *testing.T) {
+	ctx := context.Background()
+
+	var inFlight, maxInFlight int32
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+
+		for {
+			cur := atomic.LoadInt32(&maxInFlight)
+			if n <= cur || atomic.CompareAndSwapInt32(&maxInFlight, cur, n) {
+				break
+			}
+		}
+
+		time.Sleep(20 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer s.Close()
+
+	c := New(nil, &Options{MaxConcurrent: 2})
+
+	done := make(chan error, 5)
+	for i := 0; i < 5; i++ {
+		go func() { done <- c.Get(ctx, s.URL+"/ping", nil, nil) }()
+	}
+
+	for i := 0; i < 5; i++ {
+		require.NoError(t, <-done)
+	}
+
+	assert.LessOrEqual(t, atomic.LoadInt32(&maxInFlight), int32(2))
+}
+
+func TestHostLimiterThrottleIsTemporary(t *testing.T) {
+	hl := newHostLimiter(RateLimiterConfig{RequestsPerSecond: 10, Burst: 1})
+	base := hl.limiter.Limit()
+
+	hl.throttle(30 * time.Millisecond)
+	assert.Less(t, hl.limiter.Limit(), base, "throttle should reduce the rate immediately")
+
+	time.Sleep(60 * time.Millisecond)
+	assert.Equal(t, base, hl.limiter.Limit(), "rate should be restored once the blocked window passes")
+}
+
+func TestRateLimiterPerHost(t *testing.T) {
+	rl := newRateLimiter(&RateLimiterConfig{RequestsPerSecond: 5, Burst: 1, PerHost: true})
+
+	a := rl.forHost("a.example.com")
+	b := rl.forHost("b.example.com")
+
+	assert.NotSame(t, a, b, "different hosts should get independent limiters")
+	assert.Same(t, a, rl.forHost("a.example.com"), "the same host should reuse its limiter")
+}
+
+func TestRetryAfterFeedsRateLimiter(t *testing.T) {
+	ctx := context.Background()
+
+	var attempts int32
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer s.Close()
+
+	c := New(nil, &Options{
+		Retry:       &RetryPolicy{MaxAttempts: 2, BaseDelay: time.Microsecond, MaxDelay: time.Microsecond},
+		RateLimiter: &RateLimiterConfig{RequestsPerSecond: 100, Burst: 10},
+	})
+
+	u, err := url.Parse(s.URL)
+	require.NoError(t, err)
+
+	hl := c.limiter.forHost(u.Host)
+
+	done := make(chan error, 1)
+	go func() { done <- c.Get(ctx, s.URL+"/limited", nil, nil) }()
+
+	// Check the limiter is throttled while the retry is still sleeping out
+	// the 1s Retry-After, well before throttle's restore fires.
+	time.Sleep(200 * time.Millisecond)
+	assert.Less(t, float64(hl.limiter.Limit()), 100.0, "a 429 with Retry-After should throttle that host's limiter")
+
+	require.NoError(t, <-done)
+}
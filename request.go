@@ -0,0 +1,274 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"strings"
+)
+
+// MultipartFile describes a single file part for RequestBuilder.Multipart.
+// Content is streamed, not buffered, so it is safe to pass a large file or
+// other io.Reader.
+type MultipartFile struct {
+	FieldName string
+	FileName  string
+	Content   io.Reader
+}
+
+// quoteEscaper matches the escaping mime/multipart applies to field and file
+// names in the Content-Disposition header.
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+// RequestBuilder builds a request that needs more than the byte-slice
+// payload the Get/Post/Put/Patch/Delete methods support: a streaming body,
+// multipart file upload, form encoding, or a non-JSON response. Obtain one
+// via Client.Request.
+type RequestBuilder struct {
+	client *Client
+	ctx    context.Context
+	method string
+	url    string
+
+	header     http.Header
+	respHeader *http.Header
+
+	body        io.Reader
+	contentType string
+
+	// multipartFields/multipartFiles are staged by Multipart, and the pipe
+	// that streams them is only set up in Do. Starting it eagerly would
+	// leak a goroutine forever if the builder were ever abandoned instead
+	// of sent.
+	multipart       bool
+	multipartFields map[string]string
+	multipartFiles  []MultipartFile
+
+	decode func(*http.Response) error
+
+	err error
+}
+
+// Request starts building a request for method and apiURL. Use the Body,
+// JSON, Form, or Multipart methods to attach a payload, and Into, IntoXML, or
+// Download to consume the response, then call Do.
+func (c *Client) Request(ctx context.Context, method, apiURL string) *RequestBuilder {
+	return &RequestBuilder{
+		client: c,
+		ctx:    ctx,
+		method: method,
+		url:    apiURL,
+		header: http.Header{},
+	}
+}
+
+// Header sets a single request header, overwriting any existing value.
+func (b *RequestBuilder) Header(key, value string) *RequestBuilder {
+	b.header.Set(key, value)
+	return b
+}
+
+// Headers merges h into the request headers.
+func (b *RequestBuilder) Headers(h http.Header) *RequestBuilder {
+	for k, vs := range h {
+		for _, v := range vs {
+			b.header.Add(k, v)
+		}
+	}
+	return b
+}
+
+// ResponseHeaders captures the response headers into h once Do completes.
+func (b *RequestBuilder) ResponseHeaders(h *http.Header) *RequestBuilder {
+	b.respHeader = h
+	return b
+}
+
+// Body sets the request body to r, streamed as-is. It does not set a
+// Content-Type; use Header to set one if the server requires it.
+func (b *RequestBuilder) Body(r io.Reader) *RequestBuilder {
+	b.body = r
+	return b
+}
+
+// JSON encodes v as the request body with an "application/json" Content-Type.
+func (b *RequestBuilder) JSON(v any) *RequestBuilder {
+	buf := &bytes.Buffer{}
+	if err := json.NewEncoder(buf).Encode(v); err != nil {
+		b.err = fmt.Errorf("failed to encode JSON request body: %w", err)
+		return b
+	}
+
+	b.body = buf
+	b.contentType = "application/json"
+	return b
+}
+
+// Form encodes values as the request body with an
+// "application/x-www-form-urlencoded" Content-Type.
+func (b *RequestBuilder) Form(values url.Values) *RequestBuilder {
+	b.body = strings.NewReader(values.Encode())
+	b.contentType = "application/x-www-form-urlencoded"
+	return b
+}
+
+// Multipart builds a "multipart/form-data" body from fields and files. File
+// content is streamed through a pipe rather than buffered, and each file's
+// Content-Type is sniffed from its first bytes. The pipe isn't started until
+// Do is called, so an abandoned builder doesn't leak a goroutine.
+func (b *RequestBuilder) Multipart(fields map[string]string, files ...MultipartFile) *RequestBuilder {
+	b.multipart = true
+	b.multipartFields = fields
+	b.multipartFiles = files
+	return b
+}
+
+// startMultipart wires up the pipe and its writer goroutine. It's only
+// called from Do, once we know the request is actually being sent.
+func (b *RequestBuilder) startMultipart() {
+	pr, pw := io.Pipe()
+	mw := multipart.NewWriter(pw)
+
+	go func() {
+		err := writeMultipart(mw, b.multipartFields, b.multipartFiles)
+		closeErr := mw.Close()
+		if err == nil {
+			err = closeErr
+		}
+		_ = pw.CloseWithError(err)
+	}()
+
+	b.body = pr
+	b.contentType = mw.FormDataContentType()
+}
+
+func writeMultipart(mw *multipart.Writer, fields map[string]string, files []MultipartFile) error {
+	for k, v := range fields {
+		if err := mw.WriteField(k, v); err != nil {
+			return err
+		}
+	}
+
+	for _, f := range files {
+		part, content, err := createFormFilePart(mw, f.FieldName, f.FileName, f.Content)
+		if err != nil {
+			return err
+		}
+
+		if _, err := io.Copy(part, content); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// createFormFilePart creates a multipart part for f, sniffing its
+// Content-Type from the first 512 bytes read from content. It returns the
+// part to write into along with a reader that replays the sniffed bytes
+// ahead of the remainder of content, so no data is lost.
+func createFormFilePart(mw *multipart.Writer, fieldName, fileName string, content io.Reader) (io.Writer, io.Reader, error) {
+	peek := make([]byte, 512)
+	n, _ := io.ReadFull(content, peek)
+	peek = peek[:n]
+
+	h := make(textproto.MIMEHeader)
+	h.Set("Content-Disposition", fmt.Sprintf(
+		`form-data; name="%s"; filename="%s"`,
+		quoteEscaper.Replace(fieldName),
+		quoteEscaper.Replace(fileName),
+	))
+	h.Set("Content-Type", http.DetectContentType(peek))
+
+	part, err := mw.CreatePart(h)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return part, io.MultiReader(bytes.NewReader(peek), content), nil
+}
+
+// Into decodes the response body as JSON into v.
+func (b *RequestBuilder) Into(v any) *RequestBuilder {
+	b.decode = func(resp *http.Response) error {
+		if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+			return fmt.Errorf("could not parse JSON response body [%s:%s]: %w", resp.Request.Method, resp.Request.URL, err)
+		}
+		return nil
+	}
+	return b
+}
+
+// IntoXML decodes the response body as XML into v.
+func (b *RequestBuilder) IntoXML(v any) *RequestBuilder {
+	b.decode = func(resp *http.Response) error {
+		if err := xml.NewDecoder(resp.Body).Decode(v); err != nil {
+			return fmt.Errorf("could not parse XML response body [%s:%s]: %w", resp.Request.Method, resp.Request.URL, err)
+		}
+		return nil
+	}
+	return b
+}
+
+// Download streams the response body to w, without buffering it in memory.
+// Use this for large downloads in place of Into/IntoXML.
+func (b *RequestBuilder) Download(w io.Writer) *RequestBuilder {
+	b.decode = func(resp *http.Response) error {
+		if _, err := io.Copy(w, resp.Body); err != nil {
+			return fmt.Errorf("failed to stream response body [%s:%s]: %w", resp.Request.Method, resp.Request.URL, err)
+		}
+		return nil
+	}
+	return b
+}
+
+// Do sends the request and, if Into/IntoXML/Download was called, decodes the
+// response into the target they were given.
+func (b *RequestBuilder) Do() error {
+	if b.err != nil {
+		return b.err
+	}
+
+	if b.multipart {
+		b.startMultipart()
+	}
+
+	req, err := http.NewRequest(b.method, b.url, b.body)
+	if err != nil {
+		return fmt.Errorf(failMsg, b.method, err)
+	}
+	req = req.WithContext(b.ctx)
+	req.Header = b.header
+
+	if b.contentType != "" && req.Header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", b.contentType)
+	}
+
+	resp, err := b.client.cachedDo(req)
+	if err != nil {
+		return err
+	}
+
+	if resp == nil {
+		return ErrNilResponse
+	}
+	defer resp.Body.Close()
+
+	if b.respHeader != nil {
+		*b.respHeader = resp.Header.Clone()
+	}
+
+	if b.decode == nil {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+
+	return b.decode(resp)
+}
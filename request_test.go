@@ -0,0 +1,144 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testXMLPayload struct {
+	XMLName xml.Name `xml:"payload"`
+	Status  string   `xml:"status"`
+}
+
+func TestRequestBuilderForm(t *testing.T) {
+	ctx := context.Background()
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/x-www-form-urlencoded", r.Header.Get("Content-Type"))
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, "bar", r.PostForm.Get("foo"))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer s.Close()
+
+	c := New(nil, &Options{})
+
+	err := c.Request(ctx, http.MethodPost, s.URL+"/submit").
+		Form(url.Values{"foo": {"bar"}}).
+		Do()
+	require.NoError(t, err)
+}
+
+func TestRequestBuilderJSONIntoAndHeaders(t *testing.T) {
+	ctx := context.Background()
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		w.Header().Set("X-Extra", "yes")
+		w.Write([]byte(`{"status":"OK"}`)) //nolint
+	}))
+	defer s.Close()
+
+	c := New(nil, &Options{})
+
+	var result testPayload
+	var respHeader http.Header
+
+	err := c.Request(ctx, http.MethodPost, s.URL+"/submit").
+		JSON(testPayload{Status: "sent"}).
+		Into(&result).
+		ResponseHeaders(&respHeader).
+		Do()
+	require.NoError(t, err)
+	assert.Equal(t, "OK", result.Status)
+	assert.Equal(t, "yes", respHeader.Get("X-Extra"))
+}
+
+func TestRequestBuilderMultipart(t *testing.T) {
+	ctx := context.Background()
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseMultipartForm(1<<20))
+		assert.Equal(t, "bar", r.FormValue("foo"))
+
+		f, header, err := r.FormFile("file")
+		require.NoError(t, err)
+		defer f.Close()
+
+		content, err := io.ReadAll(f)
+		require.NoError(t, err)
+		assert.Equal(t, "hello world", string(content))
+		assert.Equal(t, "hello.txt", header.Filename)
+
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer s.Close()
+
+	c := New(nil, &Options{})
+
+	err := c.Request(ctx, http.MethodPost, s.URL+"/upload").
+		Multipart(
+			map[string]string{"foo": "bar"},
+			MultipartFile{FieldName: "file", FileName: "hello.txt", Content: strings.NewReader("hello world")},
+		).
+		Do()
+	require.NoError(t, err)
+}
+
+func TestRequestBuilderMultipartNotStartedUntilDo(t *testing.T) {
+	ctx := context.Background()
+
+	b := New(nil, &Options{}).Request(ctx, http.MethodPost, "http://example.invalid/upload").
+		Multipart(map[string]string{"foo": "bar"})
+
+	assert.Nil(t, b.body, "the multipart pipe/goroutine must not start until Do is called")
+}
+
+func TestRequestBuilderDownload(t *testing.T) {
+	ctx := context.Background()
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("raw bytes"))
+	}))
+	defer s.Close()
+
+	c := New(nil, &Options{})
+
+	var buf bytes.Buffer
+
+	err := c.Request(ctx, http.MethodGet, s.URL+"/file").
+		Download(&buf).
+		Do()
+	require.NoError(t, err)
+	assert.Equal(t, "raw bytes", buf.String())
+}
+
+func TestRequestBuilderIntoXML(t *testing.T) {
+	ctx := context.Background()
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/xml")
+		_, _ = w.Write([]byte(`<payload><status>OK</status></payload>`))
+	}))
+	defer s.Close()
+
+	c := New(nil, &Options{})
+
+	var result testXMLPayload
+
+	err := c.Request(ctx, http.MethodGet, s.URL+"/xml").
+		IntoXML(&result).
+		Do()
+	require.NoError(t, err)
+	assert.Equal(t, "OK", result.Status)
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	cryptorand "crypto/rand"
+	"encoding/hex"
+	"net/http"
+)
+
+// RequestIDHeader is the header RequestIDMiddleware populates.
+const RequestIDHeader = "X-Request-ID"
+
+// RequestIDMiddleware stamps every outgoing request with a random
+// X-Request-ID header, unless the caller already set one, so it can be
+// correlated with server-side logs.
+func RequestIDMiddleware() Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			if r.Header.Get(RequestIDHeader) == "" {
+				r.Header.Set(RequestIDHeader, newRequestID())
+			}
+			return next.RoundTrip(r)
+		})
+	}
+}
+
+func newRequestID() string {
+	var b [16]byte
+	_, _ = cryptorand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
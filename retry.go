@@ -0,0 +1,178 @@
+package main
+
+import (
+	"errors"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultRetryBaseDelay = 100 * time.Millisecond
+	defaultRetryMaxDelay  = 10 * time.Second
+)
+
+// RetryPolicy configures automatic retries for requests that fail with
+// ErrRetriable (4xx/5xx responses we consider transient) or a transport-level
+// error. Delays use exponential backoff with full jitter:
+// sleep = rand(0, min(MaxDelay, BaseDelay*2^attempt)).
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first. A
+	// value of 0 or 1 disables retries.
+	MaxAttempts int
+
+	// BaseDelay is the starting delay used to compute the backoff window.
+	// Defaults to 100ms if zero.
+	BaseDelay time.Duration
+
+	// MaxDelay caps the backoff window regardless of attempt count.
+	// Defaults to 10s if zero.
+	MaxDelay time.Duration
+
+	// RetryNonIdempotent opts POST and PATCH requests into retries. By
+	// default only idempotent methods (GET, HEAD, PUT, DELETE, OPTIONS,
+	// TRACE) are retried.
+	RetryNonIdempotent bool
+}
+
+// retryAfter wraps a retriable error with a server-provided delay parsed from
+// a Retry-After header, so the retry loop can honor it instead of computing
+// its own backoff.
+type retryAfter struct {
+	err   error
+	delay time.Duration
+}
+
+func (e *retryAfter) Error() string { return e.err.Error() }
+func (e *retryAfter) Unwrap() error { return e.err }
+
+// wrapRetryAfter attaches the Retry-After delay from h to err, if present, so
+// the retry loop can honor it instead of computing its own backoff, and
+// feeds the delay back into the rate limiter for r's host, if one is
+// configured.
+func (c *Client) wrapRetryAfter(r *http.Request, err error, h http.Header) error {
+	d, ok := parseRetryAfter(h)
+	if !ok {
+		return err
+	}
+
+	if c.limiter != nil {
+		c.limiter.forHost(r.URL.Host).throttle(d)
+	}
+
+	return &retryAfter{err: err, delay: d}
+}
+
+func (c *Client) doWithRetry(r *http.Request) (*http.Response, error) {
+	policy := c.options.Retry
+	if policy == nil || policy.MaxAttempts <= 1 || !retryableMethod(r.Method, policy) {
+		return c.do(r)
+	}
+
+	var lastErr error
+	for attempt := 0; attempt < policy.MaxAttempts; attempt++ {
+		if attempt > 0 {
+			if r.Body != nil && r.GetBody == nil {
+				return nil, lastErr
+			}
+			if r.GetBody != nil {
+				body, err := r.GetBody()
+				if err != nil {
+					return nil, lastErr
+				}
+				r.Body = body
+			}
+		}
+
+		resp, err := c.do(r)
+		if err == nil {
+			return resp, nil
+		}
+		lastErr = err
+
+		if !errors.Is(err, ErrRetriable) || attempt == policy.MaxAttempts-1 {
+			return nil, err
+		}
+
+		if err := sleepForRetry(r, policy, attempt, err); err != nil {
+			return nil, err
+		}
+	}
+
+	return nil, lastErr
+}
+
+func sleepForRetry(r *http.Request, policy *RetryPolicy, attempt int, err error) error {
+	delay := backoffDelay(policy, attempt)
+
+	var ra *retryAfter
+	if errors.As(err, &ra) && ra.delay > 0 {
+		delay = ra.delay
+	}
+
+	t := time.NewTimer(delay)
+	defer t.Stop()
+
+	select {
+	case <-r.Context().Done():
+		return r.Context().Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+func backoffDelay(policy *RetryPolicy, attempt int) time.Duration {
+	base := policy.BaseDelay
+	if base <= 0 {
+		base = defaultRetryBaseDelay
+	}
+
+	cap := policy.MaxDelay
+	if cap <= 0 {
+		cap = defaultRetryMaxDelay
+	}
+
+	window := base * time.Duration(int64(1)<<uint(attempt))
+	if window <= 0 || window > cap {
+		window = cap
+	}
+
+	return time.Duration(rand.Int63n(int64(window) + 1))
+}
+
+func retryableMethod(method string, policy *RetryPolicy) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete, http.MethodOptions, http.MethodTrace:
+		return true
+	case http.MethodPost, http.MethodPatch:
+		return policy.RetryNonIdempotent
+	default:
+		return false
+	}
+}
+
+// parseRetryAfter parses the Retry-After header in either its delta-seconds
+// or HTTP-date form, per RFC 9110 §10.2.3.
+func parseRetryAfter(h http.Header) (time.Duration, bool) {
+	v := h.Get("Retry-After")
+	if v == "" {
+		return 0, false
+	}
+
+	if secs, err := strconv.Atoi(v); err == nil {
+		if secs < 0 {
+			return 0, false
+		}
+		return time.Duration(secs) * time.Second, true
+	}
+
+	if t, err := http.ParseTime(v); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d, true
+		}
+		return 0, true
+	}
+
+	return 0, false
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetrySucceedsAfterTransientFailures(t *testing.T) {
+	ctx := context.Background()
+
+	var attempts int32
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) <= 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer s.Close()
+
+	c := New(nil, &Options{
+		Retry: &RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond},
+	})
+
+	err := c.Get(ctx, s.URL+"/flaky", nil, nil)
+	require.NoError(t, err)
+	assert.EqualValues(t, 3, atomic.LoadInt32(&attempts))
+}
+
+func TestRetryHonorsRetryAfterDeltaSeconds(t *testing.T) {
+	ctx := context.Background()
+
+	var attempts int32
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer s.Close()
+
+	c := New(nil, &Options{
+		// Base/MaxDelay are negligible so the test only passes if the
+		// server's Retry-After is what's actually driving the wait.
+		Retry: &RetryPolicy{MaxAttempts: 2, BaseDelay: time.Microsecond, MaxDelay: time.Microsecond},
+	})
+
+	start := time.Now()
+	err := c.Get(ctx, s.URL+"/limited", nil, nil)
+	elapsed := time.Since(start)
+
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, atomic.LoadInt32(&attempts))
+	assert.GreaterOrEqual(t, elapsed, 900*time.Millisecond)
+}
+
+func TestRetryHonorsRetryAfterHTTPDate(t *testing.T) {
+	ctx := context.Background()
+
+	var attempts int32
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			// HTTP-date has only whole-second resolution, so add a 2s
+			// margin to avoid truncation shaving the delay below 1s.
+			w.Header().Set("Retry-After", time.Now().Add(2*time.Second).UTC().Format(http.TimeFormat))
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer s.Close()
+
+	c := New(nil, &Options{
+		Retry: &RetryPolicy{MaxAttempts: 2, BaseDelay: time.Microsecond, MaxDelay: time.Microsecond},
+	})
+
+	start := time.Now()
+	err := c.Get(ctx, s.URL+"/limited-date", nil, nil)
+	elapsed := time.Since(start)
+
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, atomic.LoadInt32(&attempts))
+	assert.GreaterOrEqual(t, elapsed, 900*time.Millisecond)
+}
+
+func TestRetryAbortsSleepOnContextCancellation(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer s.Close()
+
+	c := New(nil, &Options{
+		Retry: &RetryPolicy{MaxAttempts: 5, BaseDelay: 5 * time.Second, MaxDelay: 5 * time.Second},
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err := c.Get(ctx, s.URL+"/stuck", nil, nil)
+	elapsed := time.Since(start)
+
+	require.Error(t, err)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, elapsed, 500*time.Millisecond)
+}
+
+func TestRetryDoesNotRetryNonIdempotentByDefault(t *testing.T) {
+	ctx := context.Background()
+
+	var attempts int32
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer s.Close()
+
+	c := New(nil, &Options{
+		Retry: &RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond},
+	})
+
+	err := c.Post(ctx, s.URL+"/create", nil, nil, nil)
+	require.Error(t, err)
+	assert.EqualValues(t, 1, atomic.LoadInt32(&attempts))
+}
+
+func TestRetryNonIdempotentOptIn(t *testing.T) {
+	ctx := context.Background()
+
+	var attempts int32
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) <= 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer s.Close()
+
+	c := New(nil, &Options{
+		Retry: &RetryPolicy{
+			MaxAttempts:        3,
+			BaseDelay:          time.Millisecond,
+			MaxDelay:           5 * time.Millisecond,
+			RetryNonIdempotent: true,
+		},
+	})
+
+	err := c.Post(ctx, s.URL+"/create", nil, nil, nil)
+	require.NoError(t, err)
+	assert.EqualValues(t, 3, atomic.LoadInt32(&attempts))
+}